@@ -0,0 +1,153 @@
+package main
+
+import (
+	"fmt"
+	"io"
+	"net"
+	"net/url"
+	"strconv"
+)
+
+// socks5 protocol constants, as defined by RFC 1928.
+const (
+	socks5Version        = 0x05
+	socks5MethodNoAuth   = 0x00
+	socks5MethodUserPass = 0x02
+	socks5MethodNoAccept = 0xFF
+	socks5CmdConnect     = 0x01
+	socks5ATYPIPv4       = 0x01
+	socks5ATYPDomain     = 0x03
+	socks5ATYPIPv6       = 0x04
+)
+
+// socks5Dial opens a TCP connection to proxyAddr, performs the SOCKS5
+// handshake described in RFC 1928 (and the username/password
+// sub-negotiation from RFC 1929 when user is non-empty), and asks the
+// proxy to CONNECT to targetHost:targetPort. On success it returns the
+// proxy connection, which is now a transparent tunnel to the target.
+func socks5Dial(proxyAddr, user, pass, targetHost string, targetPort int) (net.Conn, error) {
+	conn, err := net.Dial("tcp", proxyAddr)
+	if err != nil {
+		return nil, fmt.Errorf("dialing proxy: %w", err)
+	}
+
+	methods := []byte{socks5MethodNoAuth}
+	if user != "" {
+		methods = []byte{socks5MethodUserPass, socks5MethodNoAuth}
+	}
+	request := append([]byte{socks5Version, byte(len(methods))}, methods...)
+	if _, err := conn.Write(request); err != nil {
+		conn.Close()
+		return nil, fmt.Errorf("sending method negotiation: %w", err)
+	}
+
+	reply := make([]byte, 2)
+	if _, err := io.ReadFull(conn, reply); err != nil {
+		conn.Close()
+		return nil, fmt.Errorf("reading method negotiation reply: %w", err)
+	}
+	if reply[0] != socks5Version {
+		conn.Close()
+		return nil, fmt.Errorf("unexpected SOCKS version in reply: %d", reply[0])
+	}
+	selectedMethod := reply[1]
+	if selectedMethod == socks5MethodNoAccept {
+		conn.Close()
+		return nil, fmt.Errorf("proxy rejected all authentication methods")
+	}
+
+	if selectedMethod == socks5MethodUserPass {
+		authRequest := []byte{0x01, byte(len(user))}
+		authRequest = append(authRequest, user...)
+		authRequest = append(authRequest, byte(len(pass)))
+		authRequest = append(authRequest, pass...)
+		if _, err := conn.Write(authRequest); err != nil {
+			conn.Close()
+			return nil, fmt.Errorf("sending username/password auth: %w", err)
+		}
+		authReply := make([]byte, 2)
+		if _, err := io.ReadFull(conn, authReply); err != nil {
+			conn.Close()
+			return nil, fmt.Errorf("reading auth reply: %w", err)
+		}
+		if authReply[1] != 0x00 {
+			conn.Close()
+			return nil, fmt.Errorf("proxy authentication failed, status %d", authReply[1])
+		}
+	}
+
+	connectRequest := []byte{socks5Version, socks5CmdConnect, 0x00, socks5ATYPDomain, byte(len(targetHost))}
+	connectRequest = append(connectRequest, targetHost...)
+	connectRequest = append(connectRequest, byte(targetPort>>8), byte(targetPort))
+	if _, err := conn.Write(connectRequest); err != nil {
+		conn.Close()
+		return nil, fmt.Errorf("sending CONNECT request: %w", err)
+	}
+
+	header := make([]byte, 4)
+	if _, err := io.ReadFull(conn, header); err != nil {
+		conn.Close()
+		return nil, fmt.Errorf("reading CONNECT reply header: %w", err)
+	}
+	if header[1] != 0x00 {
+		conn.Close()
+		return nil, fmt.Errorf("proxy CONNECT failed with reply code %d", header[1])
+	}
+
+	// Consume and discard the bound address (BND.ADDR, BND.PORT); its
+	// contents don't matter to us, but the bytes must be drained so the
+	// connection is positioned at the start of the tunneled stream.
+	var addrLen int
+	switch header[3] {
+	case socks5ATYPIPv4:
+		addrLen = net.IPv4len
+	case socks5ATYPIPv6:
+		addrLen = net.IPv6len
+	case socks5ATYPDomain:
+		lengthByte := make([]byte, 1)
+		if _, err := io.ReadFull(conn, lengthByte); err != nil {
+			conn.Close()
+			return nil, fmt.Errorf("reading bound domain length: %w", err)
+		}
+		addrLen = int(lengthByte[0])
+	default:
+		conn.Close()
+		return nil, fmt.Errorf("unsupported bound address type %d", header[3])
+	}
+	if _, err := io.ReadFull(conn, make([]byte, addrLen+2)); err != nil {
+		conn.Close()
+		return nil, fmt.Errorf("reading bound address: %w", err)
+	}
+
+	return conn, nil
+}
+
+// dialRawConn establishes the underlying transport for a test case: a
+// direct TCP connection to Host:port, or, when proxy is set, a TCP
+// connection tunneled through a SOCKS5 proxy (socks5://[user:pass@]host:port).
+func dialRawConn(port, proxy string) (net.Conn, error) {
+	if proxy == "" {
+		return net.Dial("tcp", Host+":"+port)
+	}
+
+	proxyURL, err := url.Parse(proxy)
+	if err != nil {
+		return nil, fmt.Errorf("parsing --proxy: %w", err)
+	}
+	if proxyURL.Scheme != "socks5" {
+		return nil, fmt.Errorf("unsupported proxy scheme %q, only socks5 is supported", proxyURL.Scheme)
+	}
+	user := ""
+	pass := ""
+	if proxyURL.User != nil {
+		user = proxyURL.User.Username()
+		pass, _ = proxyURL.User.Password()
+	}
+
+	targetPort, err := strconv.Atoi(port)
+	if err != nil {
+		return nil, fmt.Errorf("parsing target port: %w", err)
+	}
+
+	return socks5Dial(proxyURL.Host, user, pass, Host, targetPort)
+}
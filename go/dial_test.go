@@ -0,0 +1,109 @@
+package main
+
+import (
+	"io"
+	"net"
+	"testing"
+)
+
+// fakeSocks5Server accepts a single connection, runs the server half of
+// the SOCKS5 handshake described in RFC 1928 (always selecting no-auth
+// and succeeding the CONNECT), replying with the given bound address
+// type and bytes, then echoes whatever it's sent afterward so a test
+// can confirm the returned conn is positioned at the start of the
+// tunneled stream.
+func fakeSocks5Server(t *testing.T, atyp byte, boundAddr []byte) string {
+	t.Helper()
+
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatalf("listen: %v", err)
+	}
+	t.Cleanup(func() { listener.Close() })
+
+	go func() {
+		conn, err := listener.Accept()
+		if err != nil {
+			return
+		}
+		defer conn.Close()
+
+		// Method negotiation: VER, NMETHODS, METHODS...
+		header := make([]byte, 2)
+		if _, err := io.ReadFull(conn, header); err != nil {
+			return
+		}
+		methods := make([]byte, header[1])
+		if _, err := io.ReadFull(conn, methods); err != nil {
+			return
+		}
+		conn.Write([]byte{socks5Version, socks5MethodNoAuth})
+
+		// CONNECT request: VER, CMD, RSV, ATYP, ADDR..., PORT(2)
+		requestHeader := make([]byte, 4)
+		if _, err := io.ReadFull(conn, requestHeader); err != nil {
+			return
+		}
+		switch requestHeader[3] {
+		case socks5ATYPDomain:
+			lengthByte := make([]byte, 1)
+			io.ReadFull(conn, lengthByte)
+			io.ReadFull(conn, make([]byte, int(lengthByte[0])+2))
+		case socks5ATYPIPv4:
+			io.ReadFull(conn, make([]byte, net.IPv4len+2))
+		case socks5ATYPIPv6:
+			io.ReadFull(conn, make([]byte, net.IPv6len+2))
+		}
+
+		reply := append([]byte{socks5Version, 0x00, 0x00, atyp}, boundAddr...)
+		conn.Write(reply)
+
+		io.Copy(conn, conn)
+	}()
+
+	return listener.Addr().String()
+}
+
+func TestSocks5DialParsesBoundAddressTypes(t *testing.T) {
+	cases := []struct {
+		name      string
+		atyp      byte
+		boundAddr []byte
+	}{
+		{"ipv4", socks5ATYPIPv4, append(net.IPv4(127, 0, 0, 1).To4(), 0x1F, 0x90)},
+		{"ipv6", socks5ATYPIPv6, append(net.IPv6loopback, 0x1F, 0x90)},
+		{"domain", socks5ATYPDomain, append([]byte{byte(len("example.com"))}, append([]byte("example.com"), 0x1F, 0x90)...)},
+	}
+
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			proxyAddr := fakeSocks5Server(t, c.atyp, c.boundAddr)
+
+			conn, err := socks5Dial(proxyAddr, "", "", "target.example", 443)
+			if err != nil {
+				t.Fatalf("socks5Dial: %v", err)
+			}
+			defer conn.Close()
+
+			want := []byte("hello through the tunnel")
+			if _, err := conn.Write(want); err != nil {
+				t.Fatalf("write: %v", err)
+			}
+			got := make([]byte, len(want))
+			if _, err := io.ReadFull(conn, got); err != nil {
+				t.Fatalf("read: %v", err)
+			}
+			if string(got) != string(want) {
+				t.Errorf("echoed data = %q, want %q", got, want)
+			}
+		})
+	}
+}
+
+func TestSocks5DialRejectsUnsupportedBoundAddressType(t *testing.T) {
+	proxyAddr := fakeSocks5Server(t, 0x7F, nil)
+
+	if _, err := socks5Dial(proxyAddr, "", "", "target.example", 443); err == nil {
+		t.Fatal("expected an error for an unsupported bound address type, got nil")
+	}
+}
@@ -0,0 +1,107 @@
+package testcase
+
+import (
+	"crypto/sha256"
+	"crypto/tls"
+	"encoding/base64"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+)
+
+// expectVersion/expectALPN are optional assertions for handshake_report,
+// supplied via --expect-version/--expect-alpn; see SetExpectations.
+var expectVersion, expectALPN string
+
+// SetExpectations records the handshake_report expectations. Either may
+// be left empty to skip that assertion.
+func SetExpectations(version, alpn string) {
+	expectVersion = version
+	expectALPN = alpn
+}
+
+// HandshakeReport is the JSON shape emitted by the handshake_report test
+// case: the negotiated parameters of a completed handshake, so an
+// interop runner can diff how the handshake went across peers, not just
+// whether it completed.
+type HandshakeReport struct {
+	Version                     string   `json:"version"`
+	CipherSuite                 string   `json:"cipher_suite"`
+	NegotiatedProtocol          string   `json:"negotiated_protocol"`
+	ServerName                  string   `json:"server_name"`
+	PeerCertificateFingerprints []string `json:"peer_certificate_fingerprints"`
+	OCSPResponse                string   `json:"ocsp_response,omitempty"`
+	// SignedCertificateTimestamps holds each raw SCT, base64-encoded like
+	// OCSPResponse, rather than fingerprinted like the peer certificates:
+	// the SCT's signature, log ID, and timestamp are exactly what a
+	// cross-implementation debugging session needs to inspect.
+	SignedCertificateTimestamps []string `json:"signed_certificate_timestamps,omitempty"`
+	DidResume                   bool     `json:"did_resume"`
+	TLSUnique                   string   `json:"tls_unique"`
+}
+
+// tlsVersionName maps a tls.VersionTLSxx constant to the dotted string
+// form used on the CLI (e.g. "1.3"), so --expect-version can be compared
+// directly against it.
+func tlsVersionName(version uint16) string {
+	switch version {
+	case tls.VersionTLS10:
+		return "1.0"
+	case tls.VersionTLS11:
+		return "1.1"
+	case tls.VersionTLS12:
+		return "1.2"
+	case tls.VersionTLS13:
+		return "1.3"
+	default:
+		return fmt.Sprintf("0x%04x", version)
+	}
+}
+
+func newHandshakeReport(state tls.ConnectionState) HandshakeReport {
+	report := HandshakeReport{
+		Version:            tlsVersionName(state.Version),
+		CipherSuite:        tls.CipherSuiteName(state.CipherSuite),
+		NegotiatedProtocol: state.NegotiatedProtocol,
+		ServerName:         state.ServerName,
+		OCSPResponse:       base64.StdEncoding.EncodeToString(state.OCSPResponse),
+		DidResume:          state.DidResume,
+		TLSUnique:          hex.EncodeToString(state.TLSUnique),
+	}
+
+	for _, cert := range state.PeerCertificates {
+		fingerprint := sha256.Sum256(cert.Raw)
+		report.PeerCertificateFingerprints = append(report.PeerCertificateFingerprints, hex.EncodeToString(fingerprint[:]))
+	}
+	for _, sct := range state.SignedCertificateTimestamps {
+		report.SignedCertificateTimestamps = append(report.SignedCertificateTimestamps, base64.StdEncoding.EncodeToString(sct))
+	}
+
+	return report
+}
+
+type handshakeReportCase struct{}
+
+func (handshakeReportCase) Name() string          { return "handshake_report" }
+func (handshakeReportCase) Configure(*tls.Config) {}
+
+func (handshakeReportCase) Run(conn *tls.Conn) error {
+	report := newHandshakeReport(conn.ConnectionState())
+	encoded, err := json.Marshal(report)
+	if err != nil {
+		return fmt.Errorf("marshaling handshake report: %w", err)
+	}
+	fmt.Println(string(encoded))
+
+	if expectVersion != "" && report.Version != expectVersion {
+		return fmt.Errorf("unexpected TLS version: got %s, expected %s", report.Version, expectVersion)
+	}
+	if expectALPN != "" && report.NegotiatedProtocol != expectALPN {
+		return fmt.Errorf("unexpected ALPN protocol: got %q, expected %q", report.NegotiatedProtocol, expectALPN)
+	}
+	return nil
+}
+
+func init() {
+	Register("handshake_report", handshakeReportCase{})
+}
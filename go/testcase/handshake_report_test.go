@@ -0,0 +1,69 @@
+package testcase
+
+import (
+	"crypto/sha256"
+	"crypto/tls"
+	"crypto/x509"
+	"encoding/base64"
+	"encoding/hex"
+	"testing"
+)
+
+func TestNewHandshakeReport(t *testing.T) {
+	cert := &x509.Certificate{Raw: []byte("fake certificate bytes")}
+	wantFingerprint := sha256.Sum256(cert.Raw)
+
+	state := tls.ConnectionState{
+		Version:                     tls.VersionTLS13,
+		CipherSuite:                 tls.TLS_AES_128_GCM_SHA256,
+		NegotiatedProtocol:          "h2",
+		ServerName:                  "example.com",
+		PeerCertificates:            []*x509.Certificate{cert},
+		SignedCertificateTimestamps: [][]byte{[]byte("fake sct bytes")},
+		DidResume:                   true,
+		TLSUnique:                   []byte{0x01, 0x02},
+	}
+
+	report := newHandshakeReport(state)
+
+	if report.Version != "1.3" {
+		t.Errorf("Version = %q, want %q", report.Version, "1.3")
+	}
+	if report.NegotiatedProtocol != "h2" {
+		t.Errorf("NegotiatedProtocol = %q, want %q", report.NegotiatedProtocol, "h2")
+	}
+	if report.ServerName != "example.com" {
+		t.Errorf("ServerName = %q, want %q", report.ServerName, "example.com")
+	}
+	if !report.DidResume {
+		t.Error("DidResume = false, want true")
+	}
+	if report.TLSUnique != hex.EncodeToString([]byte{0x01, 0x02}) {
+		t.Errorf("TLSUnique = %q, want %q", report.TLSUnique, "0102")
+	}
+	if len(report.PeerCertificateFingerprints) != 1 || report.PeerCertificateFingerprints[0] != hex.EncodeToString(wantFingerprint[:]) {
+		t.Errorf("PeerCertificateFingerprints = %v, want [%s]", report.PeerCertificateFingerprints, hex.EncodeToString(wantFingerprint[:]))
+	}
+	wantSCT := base64.StdEncoding.EncodeToString([]byte("fake sct bytes"))
+	if len(report.SignedCertificateTimestamps) != 1 || report.SignedCertificateTimestamps[0] != wantSCT {
+		t.Errorf("SignedCertificateTimestamps = %v, want [%s]", report.SignedCertificateTimestamps, wantSCT)
+	}
+}
+
+func TestTLSVersionName(t *testing.T) {
+	cases := []struct {
+		version uint16
+		want    string
+	}{
+		{tls.VersionTLS10, "1.0"},
+		{tls.VersionTLS11, "1.1"},
+		{tls.VersionTLS12, "1.2"},
+		{tls.VersionTLS13, "1.3"},
+		{0x9999, "0x9999"},
+	}
+	for _, c := range cases {
+		if got := tlsVersionName(c.version); got != c.want {
+			t.Errorf("tlsVersionName(0x%04x) = %q, want %q", c.version, got, c.want)
+		}
+	}
+}
@@ -0,0 +1,9 @@
+//go:build tls_fork
+
+package testcase
+
+// requireKeyUpdates is true when built against a crypto/tls fork that
+// implements keyUpdateSender (build with -tags tls_fork): on such a
+// build, failing to drive any KeyUpdate is a real interop bug, not an
+// expected toolchain limitation.
+const requireKeyUpdates = true
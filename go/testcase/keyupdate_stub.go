@@ -0,0 +1,13 @@
+//go:build !tls_fork
+
+package testcase
+
+// requireKeyUpdates is false on a stock toolchain build. Upstream
+// crypto/tls doesn't expose a way for the client to request a KeyUpdate,
+// and nothing in this repo vendors a fork that adds one, so there is no
+// way to drive or enforce one here. genericCase.Run reports
+// expect_key_updates cases as ErrNotImplemented on this build rather
+// than silently passing them. Build with -tags tls_fork against a
+// crypto/tls fork that implements keyUpdateSender to actually exercise
+// and enforce it.
+const requireKeyUpdates = false
@@ -0,0 +1,164 @@
+package testcase
+
+import (
+	"bufio"
+	"crypto/tls"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+)
+
+// ErrNotImplemented is returned (wrapped) by a case that can't actually
+// exercise what it claims to on this build. Callers should report it
+// distinctly from a pass or a protocol failure rather than letting it
+// read as either.
+var ErrNotImplemented = errors.New("not implemented on this build")
+
+// Spec is the declarative description of an interop scenario, loaded
+// from a JSON manifest. Most scenarios reduce to "write this, then
+// expect that"; scenarios with protocol-level logic that doesn't fit
+// that shape (handshake_report, resumption_ticket, resumption_psk)
+// register a hand-written TestCase instead, via Register in their own
+// file. JSON rather than YAML is deliberate: this module has no
+// third-party dependencies, and JSON needs none.
+type Spec struct {
+	Name                string `json:"name"`
+	RequiresMTLS        bool   `json:"requires_mtls"`
+	ClientWrites        string `json:"client_writes"`
+	ExpectedServerReads string `json:"expected_server_reads"`
+	BytePattern         string `json:"byte_pattern"`
+	Iterations          int    `json:"iterations"`
+	ExpectKeyUpdates    bool   `json:"expect_key_updates"`
+	ExpectResumption    bool   `json:"expect_resumption"`
+}
+
+// keyUpdateIntervalMB is the KeyUpdate cadence for expect_key_updates
+// cases: one KeyUpdate attempt per 16 MB read.
+const keyUpdateIntervalMB = 16
+
+// chunksPerGB is how many 1 MB chunks make up the tag value used by
+// the large data download cases: the server tags each GB of the
+// download with a single repeating byte, not each MB, so the expected
+// tag only advances once every chunksPerGB chunks.
+const chunksPerGB = 1_000
+
+// keyUpdateSender is satisfied by crypto/tls builds that export a way
+// for the client to proactively request a TLS 1.3 KeyUpdate, rather
+// than only reacting to one sent by the peer. Upstream crypto/tls does
+// not expose this on *tls.Conn, so on a stock toolchain (without
+// requireKeyUpdates) expect_key_updates cases return ErrNotImplemented
+// before reaching the assertion below.
+type keyUpdateSender interface {
+	SendKeyUpdate(updateRequested bool) error
+}
+
+// genericCase drives a Spec: write ClientWrites (if set), read and
+// compare ExpectedServerReads (if set), then read Iterations 1 MB
+// chunks tagged with an incrementing byte (BytePattern just documents
+// the tagging scheme for a reader of the manifest), optionally driving
+// a KeyUpdate every keyUpdateIntervalMB chunks.
+type genericCase struct {
+	spec Spec
+}
+
+func (c *genericCase) Name() string { return c.spec.Name }
+
+func (c *genericCase) Configure(cfg *tls.Config) {
+	if c.spec.RequiresMTLS {
+		cfg.Certificates = []tls.Certificate{clientCertificate}
+	}
+}
+
+// expectedDownloadTag returns the tag byte the server is expected to
+// have stamped on chunk chunkIndex (a flat 1 MB chunk index across the
+// whole download). The server tags a whole GB at a time, so the
+// expected value only advances once every chunksPerGB chunks.
+func expectedDownloadTag(chunkIndex int) int {
+	return (chunkIndex / chunksPerGB) % 255
+}
+
+func (c *genericCase) Run(conn *tls.Conn) error {
+	reader := bufio.NewReader(conn)
+	writer := bufio.NewWriter(conn)
+
+	if c.spec.ClientWrites != "" {
+		if _, err := writer.WriteString(c.spec.ClientWrites); err != nil {
+			return fmt.Errorf("writing client data: %w", err)
+		}
+		if err := writer.Flush(); err != nil {
+			return fmt.Errorf("flushing client data: %w", err)
+		}
+	}
+
+	if c.spec.ExpectedServerReads != "" {
+		got := make([]byte, len(c.spec.ExpectedServerReads))
+		if _, err := io.ReadFull(reader, got); err != nil {
+			return fmt.Errorf("reading server data: %w", err)
+		}
+		if string(got) != c.spec.ExpectedServerReads {
+			return fmt.Errorf("unexpected server data: got %q, want %q", got, c.spec.ExpectedServerReads)
+		}
+	}
+
+	if c.spec.ExpectKeyUpdates && !requireKeyUpdates {
+		return fmt.Errorf("%s: %w (build with -tags tls_fork against a crypto/tls fork that implements SendKeyUpdate)", c.spec.Name, ErrNotImplemented)
+	}
+
+	if c.spec.Iterations > 0 {
+		keyUpdatesRequested := 0
+		buffer := make([]byte, 1_000_000)
+		for i := 0; i < c.spec.Iterations; i++ {
+			if _, err := io.ReadFull(reader, buffer); err != nil {
+				return fmt.Errorf("reading chunk %d: %w", i, err)
+			}
+			if int(buffer[0]) != expectedDownloadTag(i) {
+				return fmt.Errorf("unexpected tag value in chunk %d", i)
+			}
+
+			if c.spec.ExpectKeyUpdates && (i+1)%keyUpdateIntervalMB == 0 {
+				sender, ok := any(conn).(keyUpdateSender)
+				if !ok {
+					return fmt.Errorf("keyUpdateSender not implemented by %T despite requireKeyUpdates", conn)
+				}
+				if err := sender.SendKeyUpdate(true); err != nil {
+					return fmt.Errorf("requesting key update: %w", err)
+				}
+				keyUpdatesRequested++
+			}
+		}
+		if c.spec.ExpectKeyUpdates {
+			fmt.Println("key updates requested:", keyUpdatesRequested)
+			if keyUpdatesRequested == 0 {
+				return fmt.Errorf("no KeyUpdate was driven despite requireKeyUpdates")
+			}
+		}
+	}
+
+	return nil
+}
+
+// LoadManifest reads a JSON array of Specs from path and registers a
+// genericCase for each one that isn't already registered, so a
+// hand-written TestCase (e.g. resumption_psk) always wins over a
+// manifest entry of the same name.
+func LoadManifest(path string) error {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return fmt.Errorf("reading manifest: %w", err)
+	}
+
+	var specs []Spec
+	if err := json.Unmarshal(data, &specs); err != nil {
+		return fmt.Errorf("parsing manifest: %w", err)
+	}
+
+	for _, spec := range specs {
+		if _, exists := registry[spec.Name]; exists {
+			continue
+		}
+		Register(spec.Name, &genericCase{spec: spec})
+	}
+	return nil
+}
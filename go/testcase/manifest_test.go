@@ -0,0 +1,43 @@
+package testcase
+
+import (
+	"errors"
+	"testing"
+)
+
+func TestExpectedDownloadTag(t *testing.T) {
+	cases := []struct {
+		chunkIndex int
+		want       int
+	}{
+		{chunkIndex: 0, want: 0},
+		{chunkIndex: 999, want: 0},
+		{chunkIndex: 1000, want: 1},
+		{chunkIndex: 1999, want: 1},
+		{chunkIndex: 2000, want: 2},
+		{chunkIndex: 255 * chunksPerGB, want: 0},
+	}
+
+	for _, c := range cases {
+		if got := expectedDownloadTag(c.chunkIndex); got != c.want {
+			t.Errorf("expectedDownloadTag(%d) = %d, want %d", c.chunkIndex, got, c.want)
+		}
+	}
+}
+
+// TestGenericCaseReportsKeyUpdatesNotImplemented pins that, on a build
+// without requireKeyUpdates (i.e. any build without -tags tls_fork), an
+// expect_key_updates case reports
+// ErrNotImplemented instead of silently passing.
+func TestGenericCaseReportsKeyUpdatesNotImplemented(t *testing.T) {
+	if requireKeyUpdates {
+		t.Skip("this toolchain was built with -tags tls_fork, which can actually drive KeyUpdates")
+	}
+
+	c := &genericCase{spec: Spec{Name: "large_data_download_with_frequent_key_updates", ExpectKeyUpdates: true}}
+
+	err := c.Run(nil)
+	if !errors.Is(err, ErrNotImplemented) {
+		t.Fatalf("Run() = %v, want an error wrapping ErrNotImplemented", err)
+	}
+}
@@ -0,0 +1,235 @@
+package testcase
+
+import (
+	"crypto/tls"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"os"
+	"sync"
+	"time"
+)
+
+// sessionFile, targetHost and targetPort are supplied by main; see
+// SetSessionFile and SetTarget.
+var (
+	sessionFile string
+	targetHost  string
+	targetPort  string
+)
+
+// SetSessionFile records the --session-file path, which lets a
+// resumption ticket be persisted to (or loaded from) disk so it can be
+// handed to a separate client invocation, possibly of a different TLS
+// implementation.
+func SetSessionFile(path string) { sessionFile = path }
+
+// SetTarget records the host:port the resumption cases are run against.
+// The host, which main also sets as the tls.Config ServerName, is the
+// key crypto/tls uses into the session cache.
+func SetTarget(host, port string) {
+	targetHost = host
+	targetPort = port
+}
+
+// sessionCacheTTL bounds how long a cached resumption ticket is
+// considered usable; an expired entry is treated as a cache miss so a
+// long-running run doesn't attempt to resume with a stale ticket.
+const sessionCacheTTL = 10 * time.Minute
+
+type sessionCacheEntry struct {
+	state   *tls.ClientSessionState
+	expires time.Time
+}
+
+// fileSessionCache is a tls.ClientSessionCache with expiration, keyed by
+// the session key crypto/tls passes in (the config's ServerName). It's used instead of tls.NewLRUClientSessionCache so
+// a cached entry can also be serialized to disk via --session-file for
+// cross-implementation resumption interop.
+type fileSessionCache struct {
+	mu      sync.RWMutex
+	entries map[string]sessionCacheEntry
+}
+
+func newFileSessionCache() *fileSessionCache {
+	return &fileSessionCache{entries: make(map[string]sessionCacheEntry)}
+}
+
+func (c *fileSessionCache) Get(sessionKey string) (*tls.ClientSessionState, bool) {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+	entry, ok := c.entries[sessionKey]
+	if !ok || time.Now().After(entry.expires) {
+		return nil, false
+	}
+	return entry.state, true
+}
+
+func (c *fileSessionCache) Put(sessionKey string, cs *tls.ClientSessionState) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if cs == nil {
+		delete(c.entries, sessionKey)
+		return
+	}
+	c.entries[sessionKey] = sessionCacheEntry{state: cs, expires: time.Now().Add(sessionCacheTTL)}
+}
+
+// persistedTicket is the on-disk form of a cached resumption ticket,
+// written with --session-file so a separate client invocation - of
+// potentially a different TLS implementation - can load it and resume
+// our session. It's plain JSON with base64-encoded byte fields rather
+// than gob, which only a Go program could decode.
+type persistedTicket struct {
+	Ticket string `json:"ticket"`
+	State  string `json:"state"`
+}
+
+func saveSessionTicket(cache *fileSessionCache, sessionKey, path string) error {
+	state, ok := cache.Get(sessionKey)
+	if !ok {
+		return fmt.Errorf("no cached session for %s", sessionKey)
+	}
+	ticket, sessionState, err := state.ResumptionState()
+	if err != nil {
+		return fmt.Errorf("extracting resumption state: %w", err)
+	}
+	stateBytes, err := sessionState.Bytes()
+	if err != nil {
+		return fmt.Errorf("encoding session state: %w", err)
+	}
+
+	encoded, err := json.Marshal(persistedTicket{
+		Ticket: base64.StdEncoding.EncodeToString(ticket),
+		State:  base64.StdEncoding.EncodeToString(stateBytes),
+	})
+	if err != nil {
+		return fmt.Errorf("encoding persisted ticket: %w", err)
+	}
+	return os.WriteFile(path, encoded, 0600)
+}
+
+func loadSessionTicket(cache *fileSessionCache, sessionKey, path string) error {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return err
+	}
+
+	var saved persistedTicket
+	if err := json.Unmarshal(data, &saved); err != nil {
+		return fmt.Errorf("parsing persisted ticket: %w", err)
+	}
+	ticket, err := base64.StdEncoding.DecodeString(saved.Ticket)
+	if err != nil {
+		return fmt.Errorf("decoding ticket: %w", err)
+	}
+	stateBytes, err := base64.StdEncoding.DecodeString(saved.State)
+	if err != nil {
+		return fmt.Errorf("decoding state: %w", err)
+	}
+
+	sessionState, err := tls.ParseSessionState(stateBytes)
+	if err != nil {
+		return fmt.Errorf("parsing session state: %w", err)
+	}
+	clientState, err := tls.NewResumptionState(ticket, sessionState)
+	if err != nil {
+		return fmt.Errorf("building resumption state: %w", err)
+	}
+	cache.Put(sessionKey, clientState)
+	return nil
+}
+
+// drainPostHandshakeMessages briefly reads with a deadline so the peer
+// has a chance to deliver post-handshake messages (TLS 1.3 delivers
+// NewSessionTicket after the handshake completes) before the connection
+// is closed.
+func drainPostHandshakeMessages(conn *tls.Conn) {
+	conn.SetReadDeadline(time.Now().Add(2 * time.Second))
+	conn.Read(make([]byte, 1))
+	conn.SetReadDeadline(time.Time{})
+}
+
+// resumptionCase drives resumption_ticket (TLS 1.2 session tickets) and
+// resumption_psk (TLS 1.3 PSK). Ordinarily it performs two sequential
+// handshakes sharing a ClientSessionCache and asserts that the second
+// one resumes. When --session-file is set it instead persists (or
+// loads) a single ticket, so resumption can be exercised across two
+// separate invocations, potentially against different implementations.
+type resumptionCase struct {
+	name    string
+	version uint16
+	cache   *fileSessionCache
+}
+
+func (c *resumptionCase) Name() string { return c.name }
+
+func (c *resumptionCase) Configure(cfg *tls.Config) {
+	cfg.MinVersion = c.version
+	cfg.MaxVersion = c.version
+	c.cache = newFileSessionCache()
+	cfg.ClientSessionCache = c.cache
+}
+
+func (c *resumptionCase) Run(*tls.Conn) error {
+	return fmt.Errorf("%s must be driven via Drive, not Run", c.name)
+}
+
+func (c *resumptionCase) Drive(dial func() (*tls.Conn, error)) error {
+	sessionKey := targetHost
+
+	if sessionFile != "" {
+		loadErr := loadSessionTicket(c.cache, sessionKey, sessionFile)
+		if loadErr == nil {
+			fmt.Println("loaded session ticket from", sessionFile)
+		}
+
+		conn, err := dial()
+		if err != nil {
+			return err
+		}
+		drainPostHandshakeMessages(conn)
+		state := conn.ConnectionState()
+		conn.Close()
+
+		if err := saveSessionTicket(c.cache, sessionKey, sessionFile); err != nil {
+			fmt.Println("Error saving session ticket:", err)
+		}
+		if loadErr == nil && !state.DidResume {
+			return fmt.Errorf("failed to resume using ticket loaded from %s", sessionFile)
+		}
+		fmt.Println("DidResume:", state.DidResume)
+		return nil
+	}
+
+	first, err := dial()
+	if err != nil {
+		return err
+	}
+	drainPostHandshakeMessages(first)
+	firstState := first.ConnectionState()
+	first.Close()
+	fmt.Println("first connection established, version:", tlsVersionName(firstState.Version))
+
+	second, err := dial()
+	if err != nil {
+		return err
+	}
+	drainPostHandshakeMessages(second)
+	secondState := second.ConnectionState()
+	second.Close()
+
+	if !secondState.DidResume {
+		return fmt.Errorf("second connection did not resume the session")
+	}
+	if secondState.Version != firstState.Version {
+		return fmt.Errorf("resumed connection negotiated a different version: got %s, expected %s", tlsVersionName(secondState.Version), tlsVersionName(firstState.Version))
+	}
+	fmt.Println("resumption succeeded, DidResume:", secondState.DidResume)
+	return nil
+}
+
+func init() {
+	Register("resumption_ticket", &resumptionCase{name: "resumption_ticket", version: tls.VersionTLS12})
+	Register("resumption_psk", &resumptionCase{name: "resumption_psk", version: tls.VersionTLS13})
+}
@@ -0,0 +1,31 @@
+package testcase
+
+import (
+	"encoding/json"
+	"testing"
+)
+
+// TestPersistedTicketIsPortableJSON pins the --session-file wire format
+// to plain JSON with base64 string fields, so a non-Go peer can parse
+// it without needing to understand gob.
+func TestPersistedTicketIsPortableJSON(t *testing.T) {
+	want := persistedTicket{Ticket: "dGlja2V0", State: "c3RhdGU="}
+
+	encoded, err := json.Marshal(want)
+	if err != nil {
+		t.Fatalf("Marshal: %v", err)
+	}
+
+	const wantJSON = `{"ticket":"dGlja2V0","state":"c3RhdGU="}`
+	if string(encoded) != wantJSON {
+		t.Errorf("persistedTicket JSON = %s, want %s", encoded, wantJSON)
+	}
+
+	var got persistedTicket
+	if err := json.Unmarshal(encoded, &got); err != nil {
+		t.Fatalf("Unmarshal: %v", err)
+	}
+	if got != want {
+		t.Errorf("round trip = %+v, want %+v", got, want)
+	}
+}
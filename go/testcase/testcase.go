@@ -0,0 +1,57 @@
+// Package testcase defines the pluggable interop scenarios this client
+// can drive against a server, and a registry so main doesn't need a
+// hard-coded switch over scenario names to add one.
+package testcase
+
+import (
+	"crypto/tls"
+	"fmt"
+)
+
+// TestCase is one interop scenario. Configure lets a case adjust the
+// tls.Config before the handshake (e.g. require mTLS, pin a version).
+// Run drives the scenario once the handshake has completed.
+type TestCase interface {
+	Name() string
+	Configure(cfg *tls.Config)
+	Run(conn *tls.Conn) error
+}
+
+// MultiConn is implemented by cases that need to drive more than one
+// connection themselves, such as the resumption cases, which must
+// observe two sequential handshakes sharing a session cache. main calls
+// Drive with a dial closure instead of the usual single-conn Run.
+type MultiConn interface {
+	TestCase
+	Drive(dial func() (*tls.Conn, error)) error
+}
+
+var registry = map[string]TestCase{}
+
+// Register adds a TestCase under name. It panics on a duplicate name,
+// since that always indicates a programming error (two cases registered
+// for the same scenario) rather than something a caller should recover
+// from.
+func Register(name string, tc TestCase) {
+	if _, exists := registry[name]; exists {
+		panic(fmt.Sprintf("testcase: %q already registered", name))
+	}
+	registry[name] = tc
+}
+
+// Lookup returns the TestCase registered under name, if any.
+func Lookup(name string) (TestCase, bool) {
+	tc, ok := registry[name]
+	return tc, ok
+}
+
+// clientCertificate is supplied by main once via SetClientCertificate,
+// since it's needed by any case with requires_mtls set and a generic
+// Spec has no other way to reach it.
+var clientCertificate tls.Certificate
+
+// SetClientCertificate records the certificate generic mTLS cases
+// should present. It must be called before Configure runs on any case.
+func SetClientCertificate(cert tls.Certificate) {
+	clientCertificate = cert
+}